@@ -17,34 +17,85 @@
 package testing
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethersphere/swarm/chunk"
 )
 
+// TagCounts groups the counters tracked on a chunk.Tag, so callers don't have to pass them around
+// as six positional int64s
+type TagCounts struct {
+	Split  int64
+	Stored int64
+	Seen   int64
+	Sent   int64
+	Synced int64
+	Total  int64
+}
+
 // CheckTag checks the first tag in the api struct to be in a certain state
-func CheckTag(t *testing.T, tag *chunk.Tag, split, stored, seen, sent, synced, total int64) {
+func CheckTag(t *testing.T, tag *chunk.Tag, want TagCounts) {
 	t.Helper()
 	if tag == nil {
 		t.Fatal("no tag found")
 	}
-	tSplit := tag.Get(chunk.StateSplit)
-	if tSplit != split {
-		t.Fatalf("should have had split chunks, got %d want %d", tSplit, split)
+
+	if diff := diffTagCounts(tagCounts(tag), want); diff != "" {
+		t.Fatalf("tag counts mismatch:\n%s", diff)
+	}
+}
+
+// WaitForTag polls tag until its counters match want, failing t with a diff-formatted message
+// showing every mismatched state if they have not converged by timeout
+func WaitForTag(t *testing.T, tag *chunk.Tag, want TagCounts, timeout time.Duration) {
+	t.Helper()
+	if tag == nil {
+		t.Fatal("no tag found")
 	}
 
-	tSeen := tag.Get(chunk.StateSeen)
-	if tSeen != seen {
-		t.Fatalf("should have had seen chunks, got %d want %d", tSeen, seen)
+	deadline := time.Now().Add(timeout)
+	var diff string
+	for {
+		diff = diffTagCounts(tagCounts(tag), want)
+		if diff == "" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out after %s waiting for tag counts to converge:\n%s", timeout, diff)
+		}
+		time.Sleep(10 * time.Millisecond)
 	}
+}
 
-	tStored := tag.Get(chunk.StateStored)
-	if tStored != stored {
-		t.Fatalf("mismatch stored chunks, got %d want %d", tStored, stored)
+// tagCounts reads the current value of every counter on tag into a TagCounts
+func tagCounts(tag *chunk.Tag) TagCounts {
+	return TagCounts{
+		Split:  tag.Get(chunk.StateSplit),
+		Stored: tag.Get(chunk.StateStored),
+		Seen:   tag.Get(chunk.StateSeen),
+		Sent:   tag.Get(chunk.StateSent),
+		Synced: tag.Get(chunk.StateSynced),
+		Total:  tag.TotalCounter(),
 	}
+}
 
-	tTotal := tag.TotalCounter()
-	if tTotal != total {
-		t.Fatalf("mismatch total chunks, got %d want %d", tTotal, total)
+// diffTagCounts returns a human-readable, one-line-per-mismatch diff between got and want, or an
+// empty string if they are equal
+func diffTagCounts(got, want TagCounts) string {
+	var b strings.Builder
+	compare := func(name string, got, want int64) {
+		if got != want {
+			fmt.Fprintf(&b, "  %s: got %d, want %d\n", name, got, want)
+		}
 	}
-}
\ No newline at end of file
+	compare("split", got.Split, want.Split)
+	compare("stored", got.Stored, want.Stored)
+	compare("seen", got.Seen, want.Seen)
+	compare("sent", got.Sent, want.Sent)
+	compare("synced", got.Synced, want.Synced)
+	compare("total", got.Total, want.Total)
+	return b.String()
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package testing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/swarm/chunk"
+)
+
+func TestDiffTagCountsReportsEachMismatch(t *testing.T) {
+	got := TagCounts{Split: 1, Stored: 1, Seen: 0, Sent: 0, Synced: 0, Total: 2}
+	want := TagCounts{Split: 1, Stored: 1, Seen: 0, Sent: 1, Synced: 1, Total: 2}
+
+	diff := diffTagCounts(got, want)
+	for _, line := range []string{"sent: got 0, want 1", "synced: got 0, want 1"} {
+		if !strings.Contains(diff, line) {
+			t.Errorf("diff %q does not mention %q", diff, line)
+		}
+	}
+	if strings.Contains(diff, "split:") || strings.Contains(diff, "total:") {
+		t.Errorf("diff %q mentions a state that matched", diff)
+	}
+}
+
+func TestDiffTagCountsEmptyWhenEqual(t *testing.T) {
+	counts := TagCounts{Split: 1, Stored: 1, Seen: 1, Sent: 1, Synced: 1, Total: 1}
+	if diff := diffTagCounts(counts, counts); diff != "" {
+		t.Fatalf("expected no diff, got:\n%s", diff)
+	}
+}
+
+func TestCheckTagPassesOnMatchingCounts(t *testing.T) {
+	tag := chunk.NewTag(0, "test", 2, false)
+	tag.Inc(chunk.StateSplit)
+	tag.Inc(chunk.StateSplit)
+	tag.Inc(chunk.StateStored)
+
+	CheckTag(t, tag, TagCounts{Split: 2, Stored: 1, Total: 2})
+}
+
+func TestWaitForTagReturnsOnceCountersConverge(t *testing.T) {
+	tag := chunk.NewTag(0, "test", 1, false)
+	tag.Inc(chunk.StateSplit)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		tag.Inc(chunk.StateStored)
+	}()
+
+	WaitForTag(t, tag, TagCounts{Split: 1, Stored: 1, Total: 1}, time.Second)
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/swarm/chunk"
+)
+
+// uploadGloballyPinnedChunks uploads windowSize random, single-chunk-sized files to node with the
+// global pin flag set, returning their chunk addresses in upload order
+func uploadGloballyPinnedChunks(node string, windowSize int) ([]chunk.Address, error) {
+	addrs := make([]chunk.Address, 0, windowSize)
+
+	for i := 0; i < windowSize; i++ {
+		data := make([]byte, chunk.DefaultSize)
+		if _, err := rand.Read(data); err != nil {
+			return nil, err
+		}
+
+		addr, err := uploadRaw(node, data)
+		if err != nil {
+			return nil, fmt.Errorf("uploading chunk %d/%d: %v", i+1, windowSize, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// uploadRaw POSTs data to node's bzz-raw endpoint with the global pin flag set, returning the
+// resulting chunk address
+func uploadRaw(node string, data []byte) (chunk.Address, error) {
+	resp, err := http.Post(node+"/bzz-raw:/?pin=true", "application/octet-stream", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+
+	return chunk.Address(common.FromHex(strings.TrimSpace(string(body)))), nil
+}
+
+// waitUntilEvicted polls node's local store until none of addrs are present any more, or deadline
+// elapses, simulating neighbourhood churn pushing the window's chunks out
+func waitUntilEvicted(node string, addrs []chunk.Address, deadline time.Duration) error {
+	cutoff := time.Now().Add(deadline)
+
+	for time.Now().Before(cutoff) {
+		allEvicted := true
+		for _, addr := range addrs {
+			present, err := hasChunkLocally(node, addr)
+			if err != nil {
+				return err
+			}
+			if present {
+				allEvicted = false
+				break
+			}
+		}
+		if allEvicted {
+			return nil
+		}
+		time.Sleep(time.Second)
+	}
+	return fmt.Errorf("timed out after %s waiting for eviction", deadline)
+}
+
+// hasChunkLocally reports whether node's own local store currently holds addr
+func hasChunkLocally(node string, addr chunk.Address) (bool, error) {
+	resp, err := http.Head(node + "/bzz-chunk:/" + addr.Hex())
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+// requestChunk requests addr from node's normal bzz-raw retrieval path, which is where netstore
+// plugs in NewRecoveryHook on a miss. It returns the response status code and body so the caller
+// can classify the outcome, since the original in-process error value cannot cross the HTTP call.
+func requestChunk(node string, addr chunk.Address, deadline time.Duration) (statusCode int, body string, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), deadline)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, node+"/bzz-raw:/"+addr.Hex(), nil)
+	if err != nil {
+		return 0, "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, "", err
+	}
+	return resp.StatusCode, string(b), nil
+}
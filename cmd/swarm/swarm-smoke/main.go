@@ -0,0 +1,67 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+// Command swarm-smoke drives black-box smoke tests against a running Swarm cluster.
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/ethersphere/swarm/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	hostsFlag = cli.StringSliceFlag{
+		Name:  "hosts",
+		Usage: "comma separated list of swarm http api hosts to run the smoke test against",
+	}
+	recoveryWindowSizeFlag = cli.IntFlag{
+		Name:  "recovery.window",
+		Usage: "number of globally pinned chunks to push through the sliding window before asserting recovery",
+		Value: 100,
+	}
+	recoveryDeadlineFlag = cli.DurationFlag{
+		Name:  "recovery.deadline",
+		Usage: "maximum time to wait for the whole window to fall out of node A's local store, and separately for node B to recover each chunk",
+		Value: 5 * time.Minute,
+	}
+)
+
+func main() {
+	app := cli.NewApp()
+	app.Name = "swarm-smoke"
+	app.Usage = "smoke test runner for a live Swarm cluster"
+	app.Flags = []cli.Flag{
+		hostsFlag,
+	}
+	app.Commands = []cli.Command{
+		{
+			Name:   "recovery",
+			Usage:  "exercise the prod package's recovery pipeline across a sliding window of globally pinned chunks",
+			Action: cliRecoverySmokeTest,
+			Flags: []cli.Flag{
+				recoveryWindowSizeFlag,
+				recoveryDeadlineFlag,
+			},
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Crit(err.Error())
+	}
+}
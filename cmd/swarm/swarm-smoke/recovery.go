@@ -0,0 +1,226 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/swarm/chunk"
+	"github.com/ethersphere/swarm/log"
+	"github.com/ethersphere/swarm/prod"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// recoveryFailureClass buckets a failed recovery so regressions in one layer of the pipeline don't
+// get lost in an aggregate failure count
+type recoveryFailureClass string
+
+const (
+	recoveryFailureNone           recoveryFailureClass = ""
+	recoveryFailureFeedLookup     recoveryFailureClass = "feed-lookup"
+	recoveryFailureTrojanDelivery recoveryFailureClass = "trojan-delivery"
+	recoveryFailureReUpload       recoveryFailureClass = "re-upload"
+	recoveryFailureUnknown        recoveryFailureClass = "unknown"
+)
+
+// classifyHTTPFailure buckets a failed bzz-raw request against node B into the pipeline layer it
+// came from. A recovery failure never reaches this binary as the original Go error value produced
+// in the node's process - it only ever survives the HTTP round trip as response status and body
+// text - so this matches against the stable .Error() text of prod's typed errors rather than
+// comparing error identity, which cannot cross that boundary.
+func classifyHTTPFailure(statusCode int, body string) recoveryFailureClass {
+	switch {
+	case statusCode == http.StatusOK:
+		return recoveryFailureNone
+	case containsAny(body, prod.ErrFeedLookup, prod.ErrFeedContent, prod.ErrTargets, prod.ErrContextHash):
+		return recoveryFailureFeedLookup
+	case containsAny(body, prod.ErrRecoveryTimeout):
+		return recoveryFailureTrojanDelivery
+	case containsAny(body, prod.ErrRecoveryRejected):
+		return recoveryFailureReUpload
+	default:
+		return recoveryFailureUnknown
+	}
+}
+
+// containsAny reports whether body contains the message of any of errs
+func containsAny(body string, errs ...error) bool {
+	for _, err := range errs {
+		if strings.Contains(body, err.Error()) {
+			return true
+		}
+	}
+	return false
+}
+
+// recoveryResult is the outcome of recovering a single chunk, used to build the smoke test's summary
+type recoveryResult struct {
+	addr      chunk.Address
+	requested time.Time
+	acked     time.Time
+	class     recoveryFailureClass
+}
+
+func (r recoveryResult) ok() bool {
+	return r.class == recoveryFailureNone
+}
+
+func (r recoveryResult) latency() time.Duration {
+	return r.acked.Sub(r.requested)
+}
+
+// recoverySummary aggregates a batch of recoveryResults into the numbers the smoke test reports
+type recoverySummary struct {
+	windowSize int
+	results    []recoveryResult
+}
+
+func (s *recoverySummary) add(r recoveryResult) {
+	s.results = append(s.results, r)
+}
+
+func (s *recoverySummary) successRate() float64 {
+	if len(s.results) == 0 {
+		return 0
+	}
+	var ok int
+	for _, r := range s.results {
+		if r.ok() {
+			ok++
+		}
+	}
+	return float64(ok) / float64(len(s.results))
+}
+
+func (s *recoverySummary) failuresByClass() map[recoveryFailureClass]int {
+	counts := make(map[recoveryFailureClass]int)
+	for _, r := range s.results {
+		if !r.ok() {
+			counts[r.class]++
+		}
+	}
+	return counts
+}
+
+// failed returns the number of chunks that were not recovered, as opposed to the number of
+// distinct failure classes those misses fall into
+func (s *recoverySummary) failed() int {
+	var failed int
+	for _, r := range s.results {
+		if !r.ok() {
+			failed++
+		}
+	}
+	return failed
+}
+
+// averageLatency returns the mean request-to-ack latency across successfully recovered chunks
+func (s *recoverySummary) averageLatency() time.Duration {
+	var ok int
+	var total time.Duration
+	for _, r := range s.results {
+		if r.ok() {
+			total += r.latency()
+			ok++
+		}
+	}
+	if ok == 0 {
+		return 0
+	}
+	return total / time.Duration(ok)
+}
+
+// maxLatency returns the slowest request-to-ack latency across successfully recovered chunks
+func (s *recoverySummary) maxLatency() time.Duration {
+	var max time.Duration
+	for _, r := range s.results {
+		if r.ok() && r.latency() > max {
+			max = r.latency()
+		}
+	}
+	return max
+}
+
+func (s *recoverySummary) log() {
+	log.Info("recovery smoke test summary",
+		"window", s.windowSize,
+		"chunks", len(s.results),
+		"successRate", fmt.Sprintf("%.2f%%", s.successRate()*100),
+		"avgLatency", s.averageLatency(),
+		"maxLatency", s.maxLatency())
+	for _, r := range s.results {
+		log.Debug("chunk recovery", "addr", r.addr, "class", r.class, "latency", r.latency())
+	}
+	for class, count := range s.failuresByClass() {
+		log.Warn("recovery failures", "class", class, "count", count)
+	}
+}
+
+// cliRecoverySmokeTest mirrors the sliding-window upload/sync smoke test: it uploads a window of
+// globally pinned chunks to node A, waits for neighbourhood churn to push them out of A's local
+// store, then requests each one from node B and asserts that NewRecoveryHook repairs it from the
+// pinner network within the configured deadline.
+func cliRecoverySmokeTest(ctx *cli.Context) error {
+	windowSize := ctx.Int(recoveryWindowSizeFlag.Name)
+	deadline := ctx.Duration(recoveryDeadlineFlag.Name)
+
+	hosts := ctx.GlobalStringSlice(hostsFlag.Name)
+	if len(hosts) < 2 {
+		return fmt.Errorf("recovery smoke test requires at least 2 hosts, node A and node B")
+	}
+	nodeA, nodeB := hosts[0], hosts[1]
+
+	addrs, err := uploadGloballyPinnedChunks(nodeA, windowSize)
+	if err != nil {
+		return fmt.Errorf("uploading window to node A: %v", err)
+	}
+
+	if err := waitUntilEvicted(nodeA, addrs, deadline); err != nil {
+		return fmt.Errorf("waiting for chunks to fall out of node A's local store: %v", err)
+	}
+
+	summary := &recoverySummary{windowSize: windowSize}
+	for _, addr := range addrs {
+		summary.add(recoverChunk(nodeB, addr, deadline))
+	}
+	summary.log()
+
+	if summary.successRate() < 1 {
+		return fmt.Errorf("recovery smoke test: %d/%d chunks were not recovered within %s", summary.failed(), windowSize, deadline)
+	}
+	return nil
+}
+
+// recoverChunk requests addr from node, blocking until NewRecoveryHook either repairs it or gives
+// up, and records the request-to-ack latency and failure class for the smoke test summary
+func recoverChunk(node string, addr chunk.Address, deadline time.Duration) recoveryResult {
+	requested := time.Now()
+	statusCode, body, err := requestChunk(node, addr, deadline)
+	class := recoveryFailureUnknown
+	if err == nil {
+		class = classifyHTTPFailure(statusCode, body)
+	}
+	return recoveryResult{
+		addr:      addr,
+		requested: requested,
+		acked:     time.Now(),
+		class:     class,
+	}
+}
@@ -21,7 +21,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"time"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -29,7 +29,6 @@ import (
 	"github.com/ethersphere/swarm/pss"
 	"github.com/ethersphere/swarm/pss/trojan"
 	"github.com/ethersphere/swarm/storage/feed"
-	"github.com/ethersphere/swarm/storage/feed/lookup"
 )
 
 // RecoveryTopicText is the string used to construct the recovery topic
@@ -38,6 +37,12 @@ const RecoveryTopicText = "RECOVERY"
 // RecoveryTopic is the topic used for repairing globally pinned chunks
 var RecoveryTopic = trojan.NewTopic(RecoveryTopicText)
 
+// RecoveryAckTopicText is the string used to construct the recovery acknowledgement topic
+const RecoveryAckTopicText = "RECOVERY_ACK"
+
+// RecoveryAckTopic is the topic a pinner replies on once it has acted on a RECOVERY trojan
+var RecoveryAckTopic = trojan.NewTopic(RecoveryAckTopicText)
+
 // ErrPublisher is returned when the publisher string cannot be decoded into bytes
 var ErrPublisher = errors.New("failed to decode publisher")
 
@@ -56,54 +61,198 @@ var ErrContextHash = errors.New("failed to extract hash from context")
 // ErrTargets is returned when there is a failure to unmarshal the feed content as a trojan.Targets variable
 var ErrTargets = errors.New("failed to unmarshal targets in recovery feed content")
 
+// ErrRecoveryTimeout is returned when no pinner acknowledges a RECOVERY trojan before ctx expires
+var ErrRecoveryTimeout = errors.New("recovery: timed out waiting for pinner acknowledgement")
+
+// ErrRecoveryRejected is returned when a pinner acknowledges a RECOVERY trojan but could not repair the chunk
+var ErrRecoveryRejected = errors.New("recovery: pinner rejected repair request")
+
+// ackStatus reports the outcome of a repair attempt carried in a RECOVERY_ACK trojan
+type ackStatus byte
+
+const (
+	ackStatusOK ackStatus = iota
+	ackStatusRejected
+)
+
+// recoveryRequest is the payload carried by a RECOVERY trojan; from lets the pinner address its ack back to the requester
+type recoveryRequest struct {
+	Address chunk.Address
+	From    trojan.Targets
+}
+
+// recoveryAck is the payload carried by a RECOVERY_ACK trojan
+type recoveryAck struct {
+	Address chunk.Address
+	Status  ackStatus
+}
+
+// RecoveryStatus reports how a RECOVERY trojan fared, including the Monitor's per-target delivery
+// state, so a caller such as netstore can decide whether to retry via the fallback publisher path
+// instead of treating every send as an unconditional success.
+type RecoveryStatus struct {
+	// Acked is true when a pinner replied on RecoveryAckTopic reporting a successful repair
+	Acked bool
+	// Targets reports, per target (keyed by its hex-encoded trojan address), whether the
+	// Monitor observed the RECOVERY trojan as delivered to that target
+	Targets map[string]bool
+}
+
 // RecoveryHook defines code to be executed upon failing to retrieve pinned chunks
-type RecoveryHook func(ctx context.Context, chunkAddress chunk.Address) error
+type RecoveryHook func(ctx context.Context, chunkAddress chunk.Address) (*RecoveryStatus, error)
 
 // sender is the function call for sending trojan chunks
 type sender func(ctx context.Context, targets trojan.Targets, topic trojan.Topic, payload []byte) (*pss.Monitor, error)
 
-// NewRecoveryHook returns a new RecoveryHook with the sender function defined
-func NewRecoveryHook(send sender, handler feed.GenericHandler, fallbackPublisher string) RecoveryHook {
-	return func(ctx context.Context, chunkAddress chunk.Address) error {
-		targets, err := getPinners(ctx, handler, fallbackPublisher)
+// registerer is the function call for registering a handler against an incoming trojan topic
+type registerer func(topic trojan.Topic, handler pss.Handler)
+
+// ackWaiters multiplexes incoming RECOVERY_ACK trojans to the goroutines awaiting them, keyed by chunk address
+type ackWaiters struct {
+	mu      sync.Mutex
+	waiting map[string][]chan recoveryAck
+}
+
+func newAckWaiters() *ackWaiters {
+	return &ackWaiters{waiting: make(map[string][]chan recoveryAck)}
+}
+
+// register returns a channel that receives the next ack for addr; call cancel once done waiting on it
+func (w *ackWaiters) register(addr chunk.Address) (ch chan recoveryAck, cancel func()) {
+	ch = make(chan recoveryAck, 1)
+	key := addr.Hex()
+
+	w.mu.Lock()
+	w.waiting[key] = append(w.waiting[key], ch)
+	w.mu.Unlock()
+
+	return ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		waiters := w.waiting[key]
+		for i, c := range waiters {
+			if c == ch {
+				w.waiting[key] = append(waiters[:i], waiters[i+1:]...)
+				break
+			}
+		}
+		if len(w.waiting[key]) == 0 {
+			delete(w.waiting, key)
+		}
+	}
+}
+
+// deliver fans an ack out to every goroutine currently waiting on its chunk address
+func (w *ackWaiters) deliver(ack recoveryAck) {
+	key := ack.Address.Hex()
+
+	w.mu.Lock()
+	waiters := w.waiting[key]
+	w.mu.Unlock()
+
+	for _, ch := range waiters {
+		select {
+		case ch <- ack:
+		default:
+		}
+	}
+}
+
+// NewRecoveryHook returns a new RecoveryHook with the sender function defined. register is used to subscribe
+// to RECOVERY_ACK trojans, self is the requester's own target list used by pinners to route their ack back,
+// and fallbackPublishers is a prioritized list of publishers tried, in order, once the feed lookup for the
+// primary publisher on ctx fails.
+func NewRecoveryHook(send sender, register registerer, resolver *RecoveryFeedResolver, fallbackPublishers []string, self trojan.Targets) RecoveryHook {
+	acks := newAckWaiters()
+	register(RecoveryAckTopic, func(m trojan.Message) {
+		ack := new(recoveryAck)
+		if err := json.Unmarshal(m.Payload, ack); err != nil {
+			return
+		}
+		acks.deliver(*ack)
+	})
+
+	return func(ctx context.Context, chunkAddress chunk.Address) (*RecoveryStatus, error) {
+		targets, err := getPinners(ctx, resolver, fallbackPublishers)
+		if err != nil {
+			return nil, err
+		}
+
+		payload, err := json.Marshal(recoveryRequest{Address: chunkAddress, From: self})
+		if err != nil {
+			return nil, err
+		}
+
+		waiter, cancel := acks.register(chunkAddress)
+		defer cancel()
+
+		monitor, err := send(ctx, targets, RecoveryTopic, payload)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		payload := chunkAddress
 
-		// TODO: returned monitor should be made use of
-		if _, err := send(ctx, targets, RecoveryTopic, payload); err != nil {
-			return err
+		select {
+		case ack := <-waiter:
+			status := &RecoveryStatus{Acked: ack.Status == ackStatusOK, Targets: monitor.States()}
+			if ack.Status != ackStatusOK {
+				return status, ErrRecoveryRejected
+			}
+			return status, nil
+		case <-monitor.Done():
+			return &RecoveryStatus{Targets: monitor.States()}, ErrRecoveryTimeout
+		case <-ctx.Done():
+			return &RecoveryStatus{Targets: monitor.States()}, ErrRecoveryTimeout
 		}
-		return nil
 	}
 }
 
-// NewRepairHandler creates a repair function to re-upload globally pinned chunks to the network with the given store
-func NewRepairHandler(s *chunk.ValidatorStore) pss.Handler {
+// NewRepairHandler creates a repair function to re-upload globally pinned chunks to the network with the given
+// store, acknowledging the requester on RecoveryAckTopic once the repair has been attempted
+func NewRepairHandler(s *chunk.ValidatorStore, send sender) pss.Handler {
 	return func(m trojan.Message) {
-		chAddr := m.Payload
-		s.Set(context.Background(), chunk.ModeSetReUpload, chAddr)
+		req := new(recoveryRequest)
+		if err := json.Unmarshal(m.Payload, req); err != nil {
+			return
+		}
+
+		status := ackStatusOK
+		if err := s.Set(context.Background(), chunk.ModeSetReUpload, req.Address); err != nil {
+			status = ackStatusRejected
+		}
+
+		payload, err := json.Marshal(recoveryAck{Address: req.Address, Status: status})
+		if err != nil {
+			return
+		}
+		// best effort: if the requester can no longer be reached, the recovery simply times out on their side
+		_, _ = send(context.Background(), req.From, RecoveryAckTopic, payload)
 	}
 }
 
-// getPinners returns the specific target pinners for a corresponding chunk by consulting recovery feeds
-func getPinners(ctx context.Context, handler feed.GenericHandler, fallbackPublisher string) (trojan.Targets, error) {
+// getPinners returns the specific target pinners for a corresponding chunk by consulting recovery feeds.
+// It first tries the publisher named on ctx, then each of fallbackPublishers in order, stopping at the
+// first lookup that succeeds. If that content does not unmarshal as trojan.Targets, ErrTargets is
+// returned and no further publishers are tried.
+func getPinners(ctx context.Context, resolver *RecoveryFeedResolver, fallbackPublishers []string) (trojan.Targets, error) {
 	// query feed using recovery topic and publisher if present
 	publisher, _ := ctx.Value("publisher").(string)
-	feedContent, err := queryRecoveryFeed(ctx, RecoveryTopicText, publisher, handler)
+	feedContent, err := resolver.Lookup(ctx, RecoveryTopicText, publisher)
 
-	// if there is an error and no fallback publisher is available, fail at this point
+	// if there is an error, fall through the prioritized fallback publishers in order
 	if err != nil {
-		if fallbackPublisher == "" {
+		if len(fallbackPublishers) == 0 {
 			return nil, err
 		}
-		// query feed using recovery topic + hash and fallback publisher
 		hash, ok := ctx.Value("hash").(string)
 		if !ok {
 			return nil, ErrContextHash
 		}
-		feedContent, err = queryRecoveryFeed(ctx, RecoveryTopicText+"_"+hash, fallbackPublisher, handler)
+		for _, fallbackPublisher := range fallbackPublishers {
+			feedContent, err = resolver.Lookup(ctx, RecoveryTopicText+"_"+hash, fallbackPublisher)
+			if err == nil {
+				break
+			}
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -118,18 +267,6 @@ func getPinners(ctx context.Context, handler feed.GenericHandler, fallbackPublis
 	return *targets, nil
 }
 
-func queryRecoveryFeed(ctx context.Context, topicText string, publisher string, handler feed.GenericHandler) ([]byte, error) {
-	var content []byte
-	topic, user, err := getFeedTopicAndUser(topicText, publisher)
-	if err == nil {
-		content, err = getFeedContent(ctx, handler, topic, user)
-	}
-	if err != nil {
-		return nil, err
-	}
-	return content, err
-}
-
 func getFeedTopicAndUser(topicText string, publisher string) (feed.Topic, common.Address, error) {
 	// get feed topic from topic text
 	topic, err := feed.NewTopic(topicText, nil)
@@ -144,29 +281,6 @@ func getFeedTopicAndUser(topicText string, publisher string) (feed.Topic, common
 	return topic, user, nil
 }
 
-func getFeedContent(ctx context.Context, handler feed.GenericHandler, topic feed.Topic, user common.Address) ([]byte, error) {
-	fd := feed.Feed{
-		Topic: topic,
-		User:  user,
-	}
-	query := feed.NewQueryLatest(&fd, lookup.NoClue)
-	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
-	defer cancel()
-
-	_, err := handler.Lookup(ctx, query)
-	// feed should still be queried even if there are no updates
-	if err != nil && err.Error() != "no feed updates found" {
-		return nil, ErrFeedLookup
-	}
-
-	_, content, err := handler.GetContent(&fd)
-	if err != nil {
-		return nil, ErrFeedContent
-	}
-
-	return content, nil
-}
-
 func publisherToAddress(publisher string) (common.Address, error) {
 	publisherBytes, err := hex.DecodeString(publisher)
 	if err != nil {
@@ -0,0 +1,118 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package prod
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethersphere/swarm/chunk"
+)
+
+// NewRecoveryHook's own race between an ack, the send Monitor and ctx is not covered here: it
+// requires a real *pss.Monitor, which is constructed by the pss package outside this repository
+// checkout. That path is instead exercised end-to-end by cmd/swarm/swarm-smoke.
+
+func TestAckWaitersDeliverToRegisteredWaiter(t *testing.T) {
+	w := newAckWaiters()
+	addr := chunk.Address([]byte("chunk-address-a"))
+
+	ch, cancel := w.register(addr)
+	defer cancel()
+
+	want := recoveryAck{Address: addr, Status: ackStatusOK}
+	w.deliver(want)
+
+	select {
+	case got := <-ch:
+		if got.Status != want.Status {
+			t.Fatalf("got status %v, want %v", got.Status, want.Status)
+		}
+	default:
+		t.Fatal("expected ack to be delivered to the registered waiter")
+	}
+}
+
+func TestAckWaitersFanOutToMultipleWaiters(t *testing.T) {
+	w := newAckWaiters()
+	addr := chunk.Address([]byte("chunk-address-b"))
+
+	ch1, cancel1 := w.register(addr)
+	defer cancel1()
+	ch2, cancel2 := w.register(addr)
+	defer cancel2()
+
+	w.deliver(recoveryAck{Address: addr, Status: ackStatusOK})
+
+	for i, ch := range []chan recoveryAck{ch1, ch2} {
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Fatalf("waiter %d never received the ack", i)
+		}
+	}
+}
+
+func TestAckWaitersIgnoresAckForUnknownAddress(t *testing.T) {
+	w := newAckWaiters()
+	addr := chunk.Address([]byte("chunk-address-c"))
+	other := chunk.Address([]byte("chunk-address-d"))
+
+	ch, cancel := w.register(addr)
+	defer cancel()
+
+	w.deliver(recoveryAck{Address: other, Status: ackStatusOK})
+
+	select {
+	case ack := <-ch:
+		t.Fatalf("unexpected ack delivered: %+v", ack)
+	default:
+	}
+}
+
+func TestAckWaitersCancelRemovesWaiter(t *testing.T) {
+	w := newAckWaiters()
+	addr := chunk.Address([]byte("chunk-address-e"))
+
+	_, cancel := w.register(addr)
+	cancel()
+
+	if _, ok := w.waiting[addr.Hex()]; ok {
+		t.Fatal("expected cancel to remove the waiter entry entirely")
+	}
+
+	// delivering after cancel should not panic or block, it should simply find no waiters left
+	w.deliver(recoveryAck{Address: addr, Status: ackStatusOK})
+}
+
+func TestAckWaitersDeliverDoesNotBlockOnFullChannel(t *testing.T) {
+	w := newAckWaiters()
+	addr := chunk.Address([]byte("chunk-address-f"))
+
+	ch, cancel := w.register(addr)
+	defer cancel()
+
+	// fill the buffered channel so a second delivery would block without the select/default guard
+	w.deliver(recoveryAck{Address: addr, Status: ackStatusOK})
+	w.deliver(recoveryAck{Address: addr, Status: ackStatusRejected})
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("expected the first ack to still be readable")
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package prod
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethersphere/swarm/storage/feed"
+	"github.com/ethersphere/swarm/storage/feed/lookup"
+)
+
+// feedKey identifies a recovery feed by its topic and publishing user
+type feedKey struct {
+	topic feed.Topic
+	user  common.Address
+}
+
+// epochEntry is a cached lookup.Epoch, valid until it is older than the resolver's TTL
+type epochEntry struct {
+	epoch    lookup.Epoch
+	cachedAt time.Time
+}
+
+// resolverMetrics accumulates counters describing how effective the epoch cache is and which
+// publisher is actually serving recovery content, so operators can see which pin targets are alive
+type resolverMetrics struct {
+	mu              sync.Mutex
+	hits            uint64
+	misses          uint64
+	publisherHits   map[string]uint64
+	publisherErrors map[string]uint64
+}
+
+func newResolverMetrics() *resolverMetrics {
+	return &resolverMetrics{
+		publisherHits:   make(map[string]uint64),
+		publisherErrors: make(map[string]uint64),
+	}
+}
+
+func (m *resolverMetrics) recordCache(hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if hit {
+		m.hits++
+	} else {
+		m.misses++
+	}
+}
+
+func (m *resolverMetrics) recordPublisher(publisher string, success bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if success {
+		m.publisherHits[publisher]++
+	} else {
+		m.publisherErrors[publisher]++
+	}
+}
+
+// HitMiss returns the number of epoch cache hits and misses observed so far
+func (m *resolverMetrics) HitMiss() (hits, misses uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.hits, m.misses
+}
+
+// PublisherSuccess returns, per publisher string, how many lookups it has successfully served
+func (m *resolverMetrics) PublisherSuccess() map[string]uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]uint64, len(m.publisherHits))
+	for k, v := range m.publisherHits {
+		out[k] = v
+	}
+	return out
+}
+
+// RecoveryFeedResolver wraps a feed.GenericHandler, remembering the last successful lookup.Epoch
+// for each (topic, user) pair so that subsequent recovery feed queries can pass that epoch as a
+// hint instead of lookup.NoClue, which would otherwise force a full epoch walk on every recovery.
+// Entries older than ttl are treated as a miss and looked up fresh. It is safe for concurrent use.
+type RecoveryFeedResolver struct {
+	handler feed.GenericHandler
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[feedKey]epochEntry
+
+	Metrics *resolverMetrics
+}
+
+// NewRecoveryFeedResolver returns a resolver caching epoch hints for up to ttl
+func NewRecoveryFeedResolver(handler feed.GenericHandler, ttl time.Duration) *RecoveryFeedResolver {
+	return &RecoveryFeedResolver{
+		handler: handler,
+		ttl:     ttl,
+		entries: make(map[feedKey]epochEntry),
+		Metrics: newResolverMetrics(),
+	}
+}
+
+// Lookup queries the recovery feed identified by topicText and publisher, using a cached epoch
+// hint when available, and records the outcome for publisher in Metrics
+func (r *RecoveryFeedResolver) Lookup(ctx context.Context, topicText string, publisher string) ([]byte, error) {
+	topic, user, err := getFeedTopicAndUser(topicText, publisher)
+	if err != nil {
+		r.Metrics.recordPublisher(publisher, false)
+		return nil, err
+	}
+
+	key := feedKey{topic: topic, user: user}
+	hint := r.hintFor(key)
+
+	content, epoch, hasEpoch, err := r.getFeedContent(ctx, topic, user, hint)
+	if err != nil {
+		r.Metrics.recordPublisher(publisher, false)
+		return nil, err
+	}
+
+	// a tolerated "no feed updates found" leaves epoch at its zero value; only cache a hint we
+	// actually observed an update at, so a quiet poll doesn't evict the last real epoch
+	if hasEpoch {
+		r.remember(key, epoch)
+	}
+	r.Metrics.recordPublisher(publisher, true)
+	return content, nil
+}
+
+// hintFor returns the cached epoch for key if it is still within ttl, and lookup.NoClue otherwise
+func (r *RecoveryFeedResolver) hintFor(key feedKey) lookup.Epoch {
+	r.mu.Lock()
+	entry, ok := r.entries[key]
+	r.mu.Unlock()
+
+	if !ok || time.Since(entry.cachedAt) > r.ttl {
+		r.Metrics.recordCache(false)
+		return lookup.NoClue
+	}
+	r.Metrics.recordCache(true)
+	return entry.epoch
+}
+
+// remember caches epoch for key, refreshing its TTL
+func (r *RecoveryFeedResolver) remember(key feedKey, epoch lookup.Epoch) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[key] = epochEntry{epoch: epoch, cachedAt: time.Now()}
+}
+
+// getFeedContent looks up the given feed using hint and returns its content. hasEpoch reports
+// whether the lookup actually observed an update at epoch; it is false for a tolerated "no feed
+// updates found", whose epoch would otherwise be a meaningless zero value.
+func (r *RecoveryFeedResolver) getFeedContent(ctx context.Context, topic feed.Topic, user common.Address, hint lookup.Epoch) (content []byte, epoch lookup.Epoch, hasEpoch bool, err error) {
+	fd := feed.Feed{
+		Topic: topic,
+		User:  user,
+	}
+	query := feed.NewQueryLatest(&fd, hint)
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+
+	request, err := r.handler.Lookup(ctx, query)
+	// feed should still be queried even if there are no updates
+	if err != nil && err.Error() != "no feed updates found" {
+		return nil, lookup.Epoch{}, false, ErrFeedLookup
+	}
+
+	_, content, err = r.handler.GetContent(&fd)
+	if err != nil {
+		return nil, lookup.Epoch{}, false, ErrFeedContent
+	}
+
+	if request != nil {
+		return content, request.Epoch, true, nil
+	}
+	return content, lookup.Epoch{}, false, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package swap
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrUnknownOracleKind is returned by API.SetHoneyOracle when kind does not name a known oracle implementation
+var ErrUnknownOracleKind = errors.New("swap: unknown honey oracle kind")
+
+// ErrNoContractBackend is returned by API.SetHoneyOracle when kind is "contract" but this holder
+// was constructed without a bind.ContractBackend to read the price feed from
+var ErrNoContractBackend = errors.New("swap: no contract backend configured for this node")
+
+// oracleHolder lets the price of honey be swapped out at runtime without requiring every holder
+// of a HoneyOracle to be notified; its RetrieveRequestPrice/ChunkDeliveryPrice methods convert
+// through whichever oracle is set at the time they are called. backend is the chain connection used to
+// construct a ContractOracle on demand; it is wired in once, at node construction time, since it
+// cannot be supplied as a JSON-RPC parameter.
+type oracleHolder struct {
+	mu      sync.RWMutex
+	oracle  HoneyOracle
+	backend bind.ContractBackend
+}
+
+// NewOracleHolder returns an oracleHolder starting out with initial, using backend (which may be
+// nil if this node has no chain connection) to construct any ContractOracle requested later
+func NewOracleHolder(initial HoneyOracle, backend bind.ContractBackend) *oracleHolder {
+	return &oracleHolder{oracle: initial, backend: backend}
+}
+
+func (h *oracleHolder) get() HoneyOracle {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.oracle
+}
+
+// closer is implemented by HoneyOracle implementations that hold resources, such as a polling
+// goroutine, which must be released when they stop being the active oracle
+type closer interface {
+	Close()
+}
+
+// set replaces the active oracle with oracle, closing the one being displaced if it holds
+// resources, so that swapping oracles at runtime never leaks a polling goroutine
+func (h *oracleHolder) set(oracle HoneyOracle) {
+	h.mu.Lock()
+	prev := h.oracle
+	h.oracle = oracle
+	h.mu.Unlock()
+
+	if c, ok := prev.(closer); ok {
+		c.Close()
+	}
+}
+
+// RetrieveRequestPrice converts the RetrieveRequestPrice honey constant through the current oracle
+func (h *oracleHolder) RetrieveRequestPrice(ctx context.Context) (price *big.Int, currency string, err error) {
+	return h.get().GetPrice(ctx, RetrieveRequestPrice)
+}
+
+// ChunkDeliveryPrice converts the ChunkDeliveryPrice honey constant through the current oracle
+func (h *oracleHolder) ChunkDeliveryPrice(ctx context.Context) (price *big.Int, currency string, err error) {
+	return h.get().GetPrice(ctx, ChunkDeliveryPrice)
+}
+
+// secondsToDuration converts a whole-seconds RPC parameter into a time.Duration
+func secondsToDuration(s uint64) time.Duration {
+	return time.Duration(s) * time.Second
+}
+
+// API exposes swap-related JSON-RPC methods under the "swap" namespace
+type API struct {
+	oracle *oracleHolder
+}
+
+// NewAPI returns a swap API backed by the given oracle holder
+func NewAPI(oracle *oracleHolder) *API {
+	return &API{oracle: oracle}
+}
+
+// SetHoneyOracle swaps the oracle held by the API's oracleHolder. kind selects the
+// implementation: "fixed" installs a FixedOracle at defaultHoneyPrice in defaultCurrency, "contract" reads the rate from a deployed
+// price-feed contract at contractAddr, polled every pollSeconds and cached for cacheTTLSeconds,
+// using the contract backend this node was started with.
+//
+// When the API is registered under the "swap" JSON-RPC namespace this method is callable as
+// swap_setHoneyOracle; this package does not register the API with an RPC server itself.
+func (api *API) SetHoneyOracle(kind string, contractAddr common.Address, pollSeconds, cacheTTLSeconds uint64) error {
+	switch kind {
+	case "fixed":
+		api.oracle.set(NewFixedOracle(defaultHoneyPrice, defaultCurrency))
+	case "contract":
+		if api.oracle.backend == nil {
+			return ErrNoContractBackend
+		}
+		api.oracle.set(NewContractOracle(api.oracle.backend, contractAddr, secondsToDuration(pollSeconds), secondsToDuration(cacheTTLSeconds)))
+	default:
+		return ErrUnknownOracleKind
+	}
+	return nil
+}
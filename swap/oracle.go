@@ -0,0 +1,174 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package swap
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// priceFeedABI is the minimal ABI of the on-chain price-feed contract ContractOracle reads from.
+// It exposes a single view function returning the current honey rate and the currency it quotes.
+const priceFeedABI = `[{"constant":true,"inputs":[],"name":"currentRate","outputs":[{"name":"price","type":"uint256"},{"name":"currency","type":"string"}],"payable":false,"stateMutability":"view","type":"function"}]`
+
+var parsedPriceFeedABI abi.ABI
+
+func init() {
+	parsed, err := abi.JSON(strings.NewReader(priceFeedABI))
+	if err != nil {
+		panic(err)
+	}
+	parsedPriceFeedABI = parsed
+}
+
+// callPriceFeed calls currentRate on the price-feed contract at addr
+func callPriceFeed(opts *bind.CallOpts, backend bind.ContractBackend, addr common.Address) (*big.Int, string, error) {
+	contract := bind.NewBoundContract(addr, parsedPriceFeedABI, backend, backend, backend)
+
+	var out []interface{}
+	if err := contract.Call(opts, &out, "currentRate"); err != nil {
+		return nil, "", err
+	}
+
+	price := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	currency := *abi.ConvertType(out[1], new(string)).(*string)
+	return price, currency, nil
+}
+
+// HoneyOracle converts a quantity of honey, Swarm's internal accounting unit, into a price
+// denominated in a settlement currency. Implementations may be as simple as a fixed rate or
+// as involved as an on-chain price feed.
+type HoneyOracle interface {
+	// GetPrice returns the price of the given amount of honey, along with the currency it is denominated in
+	GetPrice(ctx context.Context, honey uint64) (price *big.Int, currency string, err error)
+}
+
+// FixedOracle is a HoneyOracle that applies a constant conversion rate, set when it is
+// constructed. With defaultHoneyPrice it preserves the pre-oracle behaviour of this package.
+type FixedOracle struct {
+	rate     uint64
+	currency string
+}
+
+// NewFixedOracle returns a FixedOracle converting honey to currency at the given constant rate
+func NewFixedOracle(rate uint64, currency string) *FixedOracle {
+	return &FixedOracle{rate: rate, currency: currency}
+}
+
+// GetPrice implements HoneyOracle
+func (o *FixedOracle) GetPrice(_ context.Context, honey uint64) (*big.Int, string, error) {
+	price := new(big.Int).Mul(new(big.Int).SetUint64(honey), new(big.Int).SetUint64(o.rate))
+	return price, o.currency, nil
+}
+
+// cachedRate is a HoneyOracle quote cached for a limited time
+type cachedRate struct {
+	price     *big.Int
+	currency  string
+	fetchedAt time.Time
+}
+
+// ContractOracle is a HoneyOracle backed by a deployed price-feed contract. The current rate is
+// polled at pollInterval and cached for cacheTTL so that GetPrice does not need to round-trip to
+// the backend on every call.
+type ContractOracle struct {
+	backend  bind.ContractBackend
+	contract common.Address
+
+	pollInterval time.Duration
+	cacheTTL     time.Duration
+
+	mu    sync.Mutex
+	cache cachedRate
+
+	closeOnce sync.Once
+	quit      chan struct{}
+}
+
+// NewContractOracle returns a ContractOracle reading its rate from the price-feed contract at
+// contract, polling it every pollInterval and serving cached quotes for up to cacheTTL
+func NewContractOracle(backend bind.ContractBackend, contract common.Address, pollInterval, cacheTTL time.Duration) *ContractOracle {
+	o := &ContractOracle{
+		backend:      backend,
+		contract:     contract,
+		pollInterval: pollInterval,
+		cacheTTL:     cacheTTL,
+		quit:         make(chan struct{}),
+	}
+	go o.poll()
+	return o
+}
+
+// Close stops the background polling loop. It is safe to call more than once.
+func (o *ContractOracle) Close() {
+	o.closeOnce.Do(func() { close(o.quit) })
+}
+
+// GetPrice implements HoneyOracle, serving a cached quote when it is still within cacheTTL and
+// otherwise fetching a fresh one from the contract
+func (o *ContractOracle) GetPrice(ctx context.Context, honey uint64) (*big.Int, string, error) {
+	o.mu.Lock()
+	cache := o.cache
+	o.mu.Unlock()
+
+	if cache.price == nil || time.Since(cache.fetchedAt) > o.cacheTTL {
+		rate, currency, err := o.fetchRate(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		cache = cachedRate{price: rate, currency: currency, fetchedAt: time.Now()}
+		o.mu.Lock()
+		o.cache = cache
+		o.mu.Unlock()
+	}
+
+	return new(big.Int).Mul(new(big.Int).SetUint64(honey), cache.price), cache.currency, nil
+}
+
+// poll refreshes the cached rate every pollInterval until Close is called
+func (o *ContractOracle) poll() {
+	ticker := time.NewTicker(o.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), o.pollInterval)
+			if rate, currency, err := o.fetchRate(ctx); err == nil {
+				o.mu.Lock()
+				o.cache = cachedRate{price: rate, currency: currency, fetchedAt: time.Now()}
+				o.mu.Unlock()
+			}
+			cancel()
+		case <-o.quit:
+			return
+		}
+	}
+}
+
+// fetchRate calls the price-feed contract for its current honey-to-currency rate
+func (o *ContractOracle) fetchRate(ctx context.Context) (*big.Int, string, error) {
+	caller := &bind.CallOpts{Context: ctx}
+	return callPriceFeed(caller, o.backend, o.contract)
+}
@@ -0,0 +1,167 @@
+// Copyright 2020 The Swarm Authors
+// This file is part of the Swarm library.
+//
+// The Swarm library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The Swarm library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the Swarm library. If not, see <http://www.gnu.org/licenses/>.
+
+package swap
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeBackend is a minimal bind.ContractBackend that answers every eth_call with the ABI-encoded
+// (price, currency) pair it was constructed with, and counts how many times it was called
+type fakeBackend struct {
+	mu       sync.Mutex
+	calls    int
+	price    *big.Int
+	currency string
+}
+
+func (f *fakeBackend) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func (f *fakeBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	f.mu.Lock()
+	f.calls++
+	price, currency := f.price, f.currency
+	f.mu.Unlock()
+	return parsedPriceFeedABI.Methods["currentRate"].Outputs.Pack(price, currency)
+}
+
+func (f *fakeBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, nil
+}
+func (f *fakeBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return nil, nil
+}
+func (f *fakeBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 0, nil
+}
+func (f *fakeBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) { return big.NewInt(0), nil }
+func (f *fakeBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return 0, nil
+}
+func (f *fakeBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error { return nil }
+func (f *fakeBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return nil, nil
+}
+func (f *fakeBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, nil
+}
+
+func TestContractOracleServesCachedQuoteWithinTTL(t *testing.T) {
+	backend := &fakeBackend{price: big.NewInt(2), currency: "ETH"}
+	oracle := NewContractOracle(backend, common.Address{}, time.Hour, time.Hour)
+	defer oracle.Close()
+
+	if _, _, err := oracle.GetPrice(context.Background(), 10); err != nil {
+		t.Fatalf("GetPrice: %v", err)
+	}
+	if _, _, err := oracle.GetPrice(context.Background(), 10); err != nil {
+		t.Fatalf("GetPrice: %v", err)
+	}
+
+	if got := backend.callCount(); got != 1 {
+		t.Fatalf("expected the second call to be served from cache, backend was called %d times", got)
+	}
+}
+
+func TestContractOracleRefetchesAfterTTLExpires(t *testing.T) {
+	backend := &fakeBackend{price: big.NewInt(2), currency: "ETH"}
+	oracle := NewContractOracle(backend, common.Address{}, time.Hour, time.Millisecond)
+	defer oracle.Close()
+
+	if _, _, err := oracle.GetPrice(context.Background(), 10); err != nil {
+		t.Fatalf("GetPrice: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, _, err := oracle.GetPrice(context.Background(), 10); err != nil {
+		t.Fatalf("GetPrice: %v", err)
+	}
+
+	if got := backend.callCount(); got != 2 {
+		t.Fatalf("expected a fresh fetch once the cache TTL expired, backend was called %d times", got)
+	}
+}
+
+func TestContractOracleGetPriceAppliesRate(t *testing.T) {
+	backend := &fakeBackend{price: big.NewInt(3), currency: "ETH"}
+	oracle := NewContractOracle(backend, common.Address{}, time.Hour, time.Hour)
+	defer oracle.Close()
+
+	price, currency, err := oracle.GetPrice(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("GetPrice: %v", err)
+	}
+	if price.Cmp(big.NewInt(30)) != 0 {
+		t.Fatalf("got price %s, want 30", price)
+	}
+	if currency != "ETH" {
+		t.Fatalf("got currency %q, want ETH", currency)
+	}
+}
+
+func TestContractOracleClosePreventsFurtherPolling(t *testing.T) {
+	backend := &fakeBackend{price: big.NewInt(1), currency: "ETH"}
+	oracle := NewContractOracle(backend, common.Address{}, 2*time.Millisecond, time.Hour)
+
+	// let it poll at least once, then close and note the count
+	time.Sleep(20 * time.Millisecond)
+	oracle.Close()
+	afterClose := backend.callCount()
+
+	time.Sleep(20 * time.Millisecond)
+	if got := backend.callCount(); got != afterClose {
+		t.Fatalf("expected no polling after Close, call count went from %d to %d", afterClose, got)
+	}
+}
+
+func TestContractOracleCloseIsIdempotent(t *testing.T) {
+	oracle := NewContractOracle(&fakeBackend{}, common.Address{}, time.Hour, time.Hour)
+	oracle.Close()
+	oracle.Close() // must not panic
+}
+
+func TestOracleHolderSetClosesDisplacedOracle(t *testing.T) {
+	backend := &fakeBackend{price: big.NewInt(1), currency: "ETH"}
+	contractOracle := NewContractOracle(backend, common.Address{}, time.Hour, time.Hour)
+
+	holder := NewOracleHolder(contractOracle, backend)
+	holder.set(NewFixedOracle(defaultHoneyPrice, defaultCurrency))
+
+	if !isClosed(contractOracle.quit) {
+		t.Fatal("expected the displaced ContractOracle to be closed")
+	}
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
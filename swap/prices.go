@@ -30,6 +30,10 @@ for which the oracle would return the price in a given currency.
 Currently the expected currency from the oracle would be wei,
 but it could potentially be any currency the oracle and Swarm support,
 allowing for a multi-currency design.
+
+The HoneyOracle interface in oracle.go is the concrete form of that oracle: oracleHolder in
+api.go converts RetrieveRequestPrice and ChunkDeliveryPrice through the active oracle when its
+methods are called. The accounting code in this package does not call oracleHolder.
 */
 
 // Placeholder prices
@@ -42,6 +46,8 @@ allowing for a multi-currency design.
 const (
 	RetrieveRequestPrice = uint64(863614458)
 	ChunkDeliveryPrice   = uint64(7772530120)
-	// default conversion of honey into output currency - currently ETH in Wei
+	// default conversion of honey into output currency - currently ETH in Wei, used by FixedOracle
 	defaultHoneyPrice = uint64(1)
+	// defaultCurrency is the currency FixedOracle reports alongside its price
+	defaultCurrency = "ETH"
 )